@@ -0,0 +1,33 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import "k8s.io/client-go/kubernetes"
+
+// Client wraps a clientset plus whatever additional state the operator needs
+// behind the typed Get/Create/Update/Delete helpers used for each resource
+// kind it manages.
+type Client struct {
+	clientset kubernetes.Interface
+}
+
+// ClientSet returns the underlying client-go clientset, for callers (like
+// events.StreamAPIEvents) that need to build their own informers instead of
+// going through Client's typed helpers.
+func (c *Client) ClientSet() kubernetes.Interface {
+	return c.clientset
+}
@@ -26,6 +26,13 @@ const (
 	Enqueuing
 	Running
 	Complete
+	Drifted
+	PullingImage
+	Scheduling
+	PodReady
+	RolloutComplete
+	RolloutFailed
+	OOMKilled
 )
 
 var _codes = []string{
@@ -33,12 +40,19 @@ var _codes = []string{
 	"status_stalled",
 	"status_error",
 	"status_oom",
-	"status_enqueuing"
-	"status_running"
-	"status_complete"
+	"status_enqueuing",
+	"status_running",
+	"status_complete",
+	"status_drifted",
+	"status_pulling_image",
+	"status_scheduling",
+	"status_pod_ready",
+	"status_rollout_complete",
+	"status_rollout_failed",
+	"status_oom_killed",
 }
 
-var _ = [1]int{}[int(Complete)-(len(_codes)-1)] // Ensure list length matches
+var _ = [1]int{}[int(OOMKilled)-(len(_codes)-1)] // Ensure list length matches
 
 var _codeMessages = []string{
 	"unknown",               // Unknown
@@ -48,9 +62,16 @@ var _codeMessages = []string{
 	"enqueuing",             // Enqueuing
 	"running",               //  Running
 	"complete",              // Complete
+	"live state has drifted from the desired spec", // Drifted
+	"pulling image",                                 // PullingImage
+	"scheduling",                                    // Scheduling
+	"pod ready",                                     // PodReady
+	"rollout complete",                              // RolloutComplete
+	"rollout failed",                                // RolloutFailed
+	"error (pod was OOM killed)",                    // OOMKilled
 }
 
-var _ = [1]int{}[int(Complete)-(len(_codeMessages)-1)] // Ensure list length matches
+var _ = [1]int{}[int(OOMKilled)-(len(_codeMessages)-1)] // Ensure list length matches
 
 func (code Code) String() string {
 	if int(code) < 0 || int(code) >= len(_codes) {
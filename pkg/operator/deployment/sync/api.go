@@ -17,9 +17,12 @@ limitations under the License.
 package sync
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/cortexlabs/cortex/pkg/lib/cron"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
@@ -30,18 +33,61 @@ import (
 	"github.com/cortexlabs/cortex/pkg/operator/autoscaler"
 	"github.com/cortexlabs/cortex/pkg/operator/cloud"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/deployment/batch"
+	"github.com/cortexlabs/cortex/pkg/operator/deployment/sync/events"
+	"github.com/cortexlabs/cortex/pkg/operator/deployment/sync/readiness"
+	"github.com/cortexlabs/cortex/pkg/operator/deployment/sync/statusplugin"
 	"github.com/cortexlabs/cortex/pkg/types/spec"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
 	kapps "k8s.io/api/apps/v1"
 	kcore "k8s.io/api/core/v1"
 	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
-var _autoscalerCrons = make(map[string]cron.Cron) // apiName -> cron
+// defaultDeploymentTimeout bounds how long UpdateAPI will wait for resources to
+// become ready when the API spec doesn't set deployment_timeout.
+const defaultDeploymentTimeout = 10 * time.Minute
 
-func UpdateAPI(apiConfig *userconfig.API, projectID string, force bool) (*spec.API, string, error) {
-	prevDeployment, prevService, prevVirtualService, err := getK8sResources(apiConfig.Name)
+var (
+	_autoscalerCronsMux sync.Mutex
+	_autoscalerCrons    = make(map[string]cron.Cron) // "cluster/apiName" -> cron
+)
+
+// UpdateAPI deploys apiConfig to every target in targets (the local cluster if
+// targets is empty), uploading its spec to each target's bucket and applying its
+// K8s resources in parallel across clusters. Per-cluster results are returned
+// keyed by ClusterTarget.Name so a single CLI call can report a clean rollup for
+// a multi-region deploy.
+func UpdateAPI(apiConfig *userconfig.API, projectID string, force bool, targets ...ClusterTarget) (map[string]*DeployResult, error) {
+	if len(targets) == 0 {
+		targets = []ClusterTarget{defaultClusterTarget()}
+	}
+
+	var resultsMux sync.Mutex
+	results := make(map[string]*DeployResult, len(targets))
+	funcs := make([]func() error, len(targets))
+	for i := range targets {
+		target := targets[i]
+		funcs[i] = func() error {
+			api, msg, err := updateAPIOnCluster(target, apiConfig, projectID, force)
+			resultsMux.Lock()
+			results[target.Name] = &DeployResult{API: api, Message: msg, Err: err}
+			resultsMux.Unlock()
+			return nil // per-cluster errors are reported in results, not surfaced as a fan-out failure
+		}
+	}
+
+	if err := parallel.RunFirstErr(funcs...); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func updateAPIOnCluster(target ClusterTarget, apiConfig *userconfig.API, projectID string, force bool) (*spec.API, string, error) {
+	prevDeployment, prevService, prevVirtualService, err := getK8sResources(target, apiConfig.Name)
 	if err != nil {
 		return nil, "", err
 	}
@@ -54,99 +100,161 @@ func UpdateAPI(apiConfig *userconfig.API, projectID string, force bool) (*spec.A
 	api := spec.GetAPISpec(apiConfig, projectID, deploymentID)
 
 	if prevDeployment == nil {
-		if err := config.AWS.UploadMsgpackToS3(api, config.Cluster.Bucket, api.Key); err != nil {
+		if err := target.AWS.UploadMsgpackToS3(api, target.Bucket, api.Key); err != nil {
 			return nil, "", errors.Wrap(err, "upload api spec")
 		}
-		if err := applyK8sResources(api, prevDeployment, prevService, prevVirtualService); err != nil {
-			go deleteK8sResources(api.Name)
+		if err := applyK8sResources(target, api, prevDeployment, prevService, prevVirtualService); err != nil {
+			go deleteK8sResources(target, api.Name)
 			return nil, "", err
 		}
-		err = addAPIToDashboard(config.Cluster.ClusterName, api.Name)
+		err = addAPIToDashboard(target.Name, api.Name)
 		if err != nil {
 			errors.PrintError(err)
 		}
-		return api, fmt.Sprintf("creating %s", api.Name), nil
+		msg := fmt.Sprintf("creating %s", api.Name)
+		if reasons, err := waitForAPIReadiness(target, api, apiConfig); err != nil {
+			errors.PrintError(err)
+		} else if len(reasons) > 0 {
+			msg = fmt.Sprintf("%s (%s)", msg, readiness.Aggregate(reasons))
+		}
+		return api, msg, nil
 	}
 
 	if !areAPIsEqual(prevDeployment, DeploymentSpec(api, prevDeployment)) {
-		isUpdating, err := isAPIUpdating(prevDeployment)
+		isUpdating, err := isAPIUpdating(target, api, prevDeployment)
 		if err != nil {
 			return nil, "", err
 		}
 		if isUpdating && !force {
 			return nil, "", ErrorAPIUpdating(api.Name)
 		}
-		if err := config.AWS.UploadMsgpackToS3(api, config.Cluster.Bucket, api.Key); err != nil {
+		if err := target.AWS.UploadMsgpackToS3(api, target.Bucket, api.Key); err != nil {
 			return nil, "", errors.Wrap(err, "upload api spec")
 		}
-		if err := applyK8sResources(api, prevDeployment, prevService, prevVirtualService); err != nil {
+		if err := applyK8sResources(target, api, prevDeployment, prevService, prevVirtualService); err != nil {
 			return nil, "", err
 		}
-		return api, fmt.Sprintf("updating %s", api.Name), nil
+		msg := fmt.Sprintf("updating %s", api.Name)
+		if reasons, err := waitForAPIReadiness(target, api, apiConfig); err != nil {
+			errors.PrintError(err)
+		} else if len(reasons) > 0 {
+			msg = fmt.Sprintf("%s (%s)", msg, readiness.Aggregate(reasons))
+		}
+		return api, msg, nil
 	}
 
 	// deployment didn't change
-	isUpdating, err := isAPIUpdating(prevDeployment)
+	isUpdating, err := isAPIUpdating(target, api, prevDeployment)
 	if err != nil {
 		return nil, "", err
 	}
 	if isUpdating {
 		return api, fmt.Sprintf("%s is already updating", api.Name), nil
 	}
+	if code, _ := DriftStatus(target, api.Name); code == batch.Drifted {
+		return api, fmt.Sprintf("%s is up to date but has drifted from its live state", api.Name), nil
+	}
 	return api, fmt.Sprintf("%s is up to date", api.Name), nil
 }
 
-func RefreshAPI(apiName string, force bool) (string, error) {
-	prevDeployment, err := config.K8s.GetDeployment(K8sName(apiName))
+// RefreshAPI re-applies apiName's last-deployed spec across every target in
+// targets (the local cluster if targets is empty), returning per-cluster errors
+// keyed by ClusterTarget.Name.
+func RefreshAPI(apiName string, force bool, targets ...ClusterTarget) map[string]error {
+	if len(targets) == 0 {
+		targets = []ClusterTarget{defaultClusterTarget()}
+	}
+
+	var resultsMux sync.Mutex
+	results := make(map[string]error, len(targets))
+	funcs := make([]func() error, len(targets))
+	for i := range targets {
+		target := targets[i]
+		funcs[i] = func() error {
+			err := refreshAPIOnCluster(target, apiName, force)
+			resultsMux.Lock()
+			results[target.Name] = err
+			resultsMux.Unlock()
+			return nil
+		}
+	}
+
+	parallel.RunFirstErr(funcs...)
+	return results
+}
+
+func refreshAPIOnCluster(target ClusterTarget, apiName string, force bool) error {
+	prevDeployment, err := target.K8s.GetDeployment(K8sName(apiName))
 	if err != nil {
-		return "", err
+		return err
 	} else if prevDeployment == nil {
-		return "", ErrorAPINotDeployed(apiName)
+		return ErrorAPINotDeployed(apiName)
 	}
 
-	isUpdating, err := isAPIUpdating(prevDeployment)
+	isUpdating, err := isAPIUpdating(target, nil, prevDeployment)
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	if isUpdating && !force {
-		return "", ErrorAPIUpdating(apiName)
+		return ErrorAPIUpdating(apiName)
 	}
 
 	apiID, err := k8s.GetLabel(prevDeployment, "apiID")
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	api, err := cloud.DownloadAPISpec(apiName, apiID)
+	apiSpec, err := cloud.DownloadAPISpec(apiName, apiID)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	api = spec.GetAPISpec(api.API, api.ProjectID, k8s.RandomName())
+	api := spec.GetAPISpec(apiSpec.API, apiSpec.ProjectID, k8s.RandomName())
 
-	if err := config.AWS.UploadMsgpackToS3(api, config.Cluster.Bucket, api.Key); err != nil {
-		return "", errors.Wrap(err, "upload api spec")
+	if err := target.AWS.UploadMsgpackToS3(api, target.Bucket, api.Key); err != nil {
+		return errors.Wrap(err, "upload api spec")
 	}
 
-	if err := applyK8sDeployment(api, prevDeployment); err != nil {
-		return "", err
+	return applyK8sDeployment(target, api, prevDeployment)
+}
+
+// DeleteAPI removes apiName from every target in targets (the local cluster if
+// targets is empty), returning per-cluster errors keyed by ClusterTarget.Name.
+func DeleteAPI(apiName string, keepCache bool, targets ...ClusterTarget) map[string]error {
+	if len(targets) == 0 {
+		targets = []ClusterTarget{defaultClusterTarget()}
+	}
+
+	var resultsMux sync.Mutex
+	results := make(map[string]error, len(targets))
+	funcs := make([]func() error, len(targets))
+	for i := range targets {
+		target := targets[i]
+		funcs[i] = func() error {
+			err := deleteAPIOnCluster(target, apiName, keepCache)
+			resultsMux.Lock()
+			results[target.Name] = err
+			resultsMux.Unlock()
+			return nil
+		}
 	}
 
-	return fmt.Sprintf("updating %s", api.Name), nil
+	parallel.RunFirstErr(funcs...)
+	return results
 }
 
-func DeleteAPI(apiName string, keepCache bool) error {
-	err := parallel.RunFirstErr(
+func deleteAPIOnCluster(target ClusterTarget, apiName string, keepCache bool) error {
+	return parallel.RunFirstErr(
 		func() error {
-			return deleteK8sResources(apiName)
+			return deleteK8sResources(target, apiName)
 		},
 		func() error {
 			if keepCache {
 				return nil
 			}
 			// best effort deletion
-			deleteS3Resources(apiName)
+			deleteS3Resources(target, apiName)
 			return nil
 		},
 		// delete api from cloudwatch
@@ -161,7 +269,7 @@ func DeleteAPI(apiName string, keepCache bool) error {
 			for i, stat := range statuses {
 				allAPINames[i] = stat.APIName
 			}
-			err = removeAPIFromDashboard(allAPINames, config.Cluster.ClusterName, apiName)
+			err = removeAPIFromDashboard(allAPINames, target.Name, apiName)
 			if err != nil {
 				errors.PrintError(err)
 				return nil
@@ -169,15 +277,9 @@ func DeleteAPI(apiName string, keepCache bool) error {
 			return nil
 		},
 	)
-
-	if err != nil {
-		return err
-	}
-
-	return nil
 }
 
-func getK8sResources(apiName string) (*kapps.Deployment, *kcore.Service, *kunstructured.Unstructured, error) {
+func getK8sResources(target ClusterTarget, apiName string) (*kapps.Deployment, *kcore.Service, *kunstructured.Unstructured, error) {
 	var deployment *kapps.Deployment
 	var service *kcore.Service
 	var virtualService *kunstructured.Unstructured
@@ -185,17 +287,17 @@ func getK8sResources(apiName string) (*kapps.Deployment, *kcore.Service, *kunstr
 	err := parallel.RunFirstErr(
 		func() error {
 			var err error
-			deployment, err = config.K8s.GetDeployment(K8sName(apiName))
+			deployment, err = target.K8s.GetDeployment(K8sName(apiName))
 			return err
 		},
 		func() error {
 			var err error
-			service, err = config.K8s.GetService(K8sName(apiName))
+			service, err = target.K8s.GetService(K8sName(apiName))
 			return err
 		},
 		func() error {
 			var err error
-			virtualService, err = config.K8s.GetVirtualService(K8sName(apiName))
+			virtualService, err = target.K8s.GetVirtualService(K8sName(apiName))
 			return err
 		},
 	)
@@ -203,124 +305,301 @@ func getK8sResources(apiName string) (*kapps.Deployment, *kcore.Service, *kunstr
 	return deployment, service, virtualService, err
 }
 
-func applyK8sResources(api *spec.API, prevDeployment *kapps.Deployment, prevService *kcore.Service, prevVirtualService *kunstructured.Unstructured) error {
-	return parallel.RunFirstErr(
+// applyK8sResources creates or updates the Deployment, Service and VirtualService
+// that back every API, plus any third-party resources the API spec declares under
+// `resources:` whose kind has a statusplugin.StatusPlugin registered for it (e.g. by
+// cmd/operator at startup). The three built-ins keep their own typed helpers below -
+// which carry Deployment-specific extras like autoscaler cron wiring and drift
+// detection - but those helpers delegate the actual create/update/delete through the
+// same plugin registry the third-party resources use, so every kind goes through one
+// lifecycle.
+func applyK8sResources(target ClusterTarget, api *spec.API, prevDeployment *kapps.Deployment, prevService *kcore.Service, prevVirtualService *kunstructured.Unstructured) error {
+	funcs := []func() error{
 		func() error {
-			return applyK8sDeployment(api, prevDeployment)
+			return applyK8sDeployment(target, api, prevDeployment)
 		},
 		func() error {
-			return applyK8sService(api, prevService)
+			return applyK8sService(target, api, prevService)
 		},
 		func() error {
-			return applyK8sVirtualService(api, prevVirtualService)
+			return applyK8sVirtualService(target, api, prevVirtualService)
 		},
-	)
+	}
+
+	for _, res := range api.ExtraResources {
+		res := res
+		plugin := statusplugin.Get(res.GroupVersionKind())
+		if plugin == nil {
+			continue
+		}
+		funcs = append(funcs, func() error {
+			prev, err := plugin.Get(target.K8s, res.GetName())
+			if err != nil {
+				return err
+			}
+			return plugin.Apply(target.K8s, res, prev)
+		})
+	}
+
+	return parallel.RunFirstErr(funcs...)
 }
 
-func applyK8sDeployment(api *spec.API, prevDeployment *kapps.Deployment) error {
+func applyK8sDeployment(target ClusterTarget, api *spec.API, prevDeployment *kapps.Deployment) error {
 	newDeployment := DeploymentSpec(api, prevDeployment)
 
-	if prevDeployment == nil {
-		_, err := config.K8s.CreateDeployment(newDeployment)
-		if err != nil {
-			return err
-		}
-	} else if prevDeployment.Status.ReadyReplicas == 0 {
-		// Delete deployment if it never became ready
-		config.K8s.DeleteDeployment(K8sName(api.Name))
-		_, err := config.K8s.CreateDeployment(newDeployment)
-		if err != nil {
-			return err
-		}
-	} else {
-		_, err := config.K8s.UpdateDeployment(newDeployment)
+	newObj, err := toUnstructured(newDeployment)
+	if err != nil {
+		return err
+	}
+
+	var prevObj *kunstructured.Unstructured
+	if prevDeployment != nil {
+		prevObj, err = toUnstructured(prevDeployment)
 		if err != nil {
 			return err
 		}
 	}
 
-	if err := UpdateAutoscalerCron(newDeployment); err != nil {
+	// create/update/never-ready-recreate all live in deploymentPlugin.Apply, so the
+	// Deployment goes through the same registry every other plugin-managed kind does
+	if err := statusplugin.Get(deploymentGVK).Apply(target.K8s, newObj, prevObj); err != nil {
 		return err
 	}
 
+	if err := UpdateAutoscalerCron(target, newDeployment); err != nil {
+		return err
+	}
+
+	StartDriftDetector(target, api.Name)
+
 	return nil
 }
 
-func UpdateAutoscalerCron(deployment *kapps.Deployment) error {
+func UpdateAutoscalerCron(target ClusterTarget, deployment *kapps.Deployment) error {
 	apiName := deployment.Labels["apiName"]
+	key := clusterAPIKey(target.Name, apiName)
 
-	if prevAutoscalerCron, ok := _autoscalerCrons[apiName]; ok {
-		prevAutoscalerCron.Cancel()
-	}
-
-	autoscaler, err := autoscaler.AutoscaleFn(deployment)
+	autoscaleFn, err := autoscaler.AutoscaleFn(deployment)
 	if err != nil {
 		return err
 	}
 
-	_autoscalerCrons[apiName] = cron.Run(autoscaler, cronErrHandler(apiName+" autoscaler"), spec.AutoscalingTickInterval)
+	_autoscalerCronsMux.Lock()
+	defer _autoscalerCronsMux.Unlock()
+
+	if prevAutoscalerCron, ok := _autoscalerCrons[key]; ok {
+		prevAutoscalerCron.Cancel()
+	}
+
+	_autoscalerCrons[key] = cron.Run(autoscaleFn, cronErrHandler(key+" autoscaler"), spec.AutoscalingTickInterval)
 
 	return nil
 }
 
-func applyK8sService(api *spec.API, prevService *kcore.Service) error {
+func applyK8sService(target ClusterTarget, api *spec.API, prevService *kcore.Service) error {
 	newService := serviceSpec(api)
 
-	if prevService == nil {
-		_, err := config.K8s.CreateService(newService)
+	newObj, err := toUnstructured(newService)
+	if err != nil {
 		return err
 	}
 
-	_, err := config.K8s.UpdateService(prevService, newService)
-	return err
+	var prevObj *kunstructured.Unstructured
+	if prevService != nil {
+		prevObj, err = toUnstructured(prevService)
+		if err != nil {
+			return err
+		}
+	}
+
+	return statusplugin.Get(serviceGVK).Apply(target.K8s, newObj, prevObj)
 }
 
-func applyK8sVirtualService(api *spec.API, prevVirtualService *kunstructured.Unstructured) error {
+func applyK8sVirtualService(target ClusterTarget, api *spec.API, prevVirtualService *kunstructured.Unstructured) error {
 	newVirtualService := virtualServiceSpec(api)
-
-	if prevVirtualService == nil {
-		_, err := config.K8s.CreateVirtualService(newVirtualService)
-		return err
-	}
-
-	_, err := config.K8s.UpdateVirtualService(prevVirtualService, newVirtualService)
-	return err
+	return statusplugin.Get(virtualServiceGVK).Apply(target.K8s, newVirtualService, prevVirtualService)
 }
 
-func deleteK8sResources(apiName string) error {
-	return parallel.RunFirstErr(
+func deleteK8sResources(target ClusterTarget, apiName string) error {
+	key := clusterAPIKey(target.Name, apiName)
+
+	funcs := []func() error{
 		func() error {
-			if autoscalerCron, ok := _autoscalerCrons[apiName]; ok {
+			_autoscalerCronsMux.Lock()
+			if autoscalerCron, ok := _autoscalerCrons[key]; ok {
 				autoscalerCron.Cancel()
-				delete(_autoscalerCrons, apiName)
+				delete(_autoscalerCrons, key)
 			}
+			_autoscalerCronsMux.Unlock()
 
-			_, err := config.K8s.DeleteDeployment(K8sName(apiName))
-			return err
+			StopDriftDetector(target, apiName)
+
+			return statusplugin.Get(deploymentGVK).Delete(target.K8s, apiName)
 		},
 		func() error {
-			_, err := config.K8s.DeleteService(K8sName(apiName))
-			return err
+			return statusplugin.Get(serviceGVK).Delete(target.K8s, apiName)
 		},
 		func() error {
-			_, err := config.K8s.DeleteVirtualService(K8sName(apiName))
-			return err
+			return statusplugin.Get(virtualServiceGVK).Delete(target.K8s, apiName)
 		},
-	)
+	}
+
+	// the extras are re-derived from the live Deployment, so they're deleted
+	// before the Deployment itself rather than alongside it
+	extrasErr := deleteExtraResources(target, apiName)
+
+	if err := parallel.RunFirstErr(funcs...); err != nil {
+		return err
+	}
+	return extrasErr
+}
+
+// deleteExtraResources removes apiName's `resources:` extras. It re-derives them
+// from the deployed spec (the same way refreshAPIOnCluster/checkDrift do) so each
+// one is deleted by the same res.GetName() it was applied under - not by apiName,
+// which only happens to match for a custom resource that shares the API's name.
+func deleteExtraResources(target ClusterTarget, apiName string) error {
+	deployment, err := target.K8s.GetDeployment(K8sName(apiName))
+	if err != nil {
+		return err
+	}
+	if deployment == nil {
+		// nothing to re-derive the spec from; nothing was ever applied either
+		return nil
+	}
+
+	apiID, err := k8s.GetLabel(deployment, "apiID")
+	if err != nil {
+		return err
+	}
+
+	apiSpec, err := cloud.DownloadAPISpec(apiName, apiID)
+	if err != nil {
+		return err
+	}
+	api := spec.GetAPISpec(apiSpec.API, apiSpec.ProjectID, deployment.Labels["deploymentID"])
+
+	funcs := make([]func() error, 0, len(api.ExtraResources))
+	for _, res := range api.ExtraResources {
+		res := res
+		plugin := statusplugin.Get(res.GroupVersionKind())
+		if plugin == nil {
+			continue
+		}
+		funcs = append(funcs, func() error {
+			return plugin.Delete(target.K8s, res.GetName())
+		})
+	}
+
+	return parallel.RunFirstErr(funcs...)
 }
 
-func deleteS3Resources(apiName string) error {
+func deleteS3Resources(target ClusterTarget, apiName string) error {
 	return parallel.RunFirstErr(
 		func() error {
 			prefix := filepath.Join("apis", apiName)
-			return config.AWS.DeleteS3Dir(config.Cluster.Bucket, prefix, true)
+			return target.AWS.DeleteS3Dir(target.Bucket, prefix, true)
 		},
 	)
 }
 
-// returns true if min_replicas are not ready and no updated replicas have errored
-func isAPIUpdating(deployment *kapps.Deployment) (bool, error) {
-	pods, err := config.K8s.ListPodsByLabel("apiName", deployment.Labels["apiName"])
+// waitForAPIReadiness blocks until the API's resources are ready on target or
+// apiConfig.DeploymentTimeout (default defaultDeploymentTimeout) elapses,
+// returning the readiness reasons observed on the final poll.
+func waitForAPIReadiness(target ClusterTarget, api *spec.API, apiConfig *userconfig.API) ([]readiness.Reason, error) {
+	timeout := defaultDeploymentTimeout
+	if apiConfig.DeploymentTimeout > 0 {
+		timeout = time.Duration(apiConfig.DeploymentTimeout) * time.Second
+	}
+	if timeout <= 0 {
+		return nil, nil
+	}
+
+	deployment, service, virtualService, err := getK8sResources(target, api.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	objs := []runtime.Object{}
+	if deployment != nil {
+		objs = append(objs, deployment)
+	}
+	if service != nil {
+		objs = append(objs, service)
+	}
+	if virtualService != nil {
+		objs = append(objs, virtualService)
+	}
+
+	waiter := &readiness.Waiter{GetFn: refreshK8sObject(target)}
+	return waiter.WaitFor(context.Background(), objs, timeout)
+}
+
+func refreshK8sObject(target ClusterTarget) func(runtime.Object) (runtime.Object, error) {
+	return func(obj runtime.Object) (runtime.Object, error) {
+		switch o := obj.(type) {
+		case *kapps.Deployment:
+			return target.K8s.GetDeployment(o.Name)
+		case *kcore.Service:
+			return target.K8s.GetService(o.Name)
+		case *kunstructured.Unstructured:
+			return target.K8s.GetVirtualService(o.GetName())
+		default:
+			return obj, nil
+		}
+	}
+}
+
+// StreamAPIEvents streams the rollout milestones (PullingImage, Scheduling, PodReady,
+// OOMKilled, ...) for apiName's pods on the local cluster as they happen, until ctx
+// is cancelled.
+func StreamAPIEvents(ctx context.Context, apiName string, out chan<- events.Event) error {
+	return events.StreamAPIEvents(ctx, config.K8s.ClientSet(), apiName, out)
+}
+
+// rolloutStatus reports why deployment's rollout hasn't finished, derived from the
+// last milestone each of its pods has reached rather than only replica counts.
+// isAPIUpdating uses only the returned code; the message (e.g. the image pull
+// error) is not included in what UpdateAPI or RefreshAPI return.
+func rolloutStatus(deployment *kapps.Deployment, pods []kcore.Pod) (batch.Code, string) {
+	worst := batch.Complete
+	worstMessage := ""
+
+	rank := map[batch.Code]int{
+		batch.Complete:      0,
+		batch.PodReady:      1,
+		batch.Scheduling:    2,
+		batch.PullingImage:  3,
+		batch.Enqueuing:     4,
+		batch.OOMKilled:     5,
+		batch.RolloutFailed: 6,
+	}
+
+	for i := range pods {
+		if !isPodSpecLatest(deployment, &pods[i]) {
+			continue
+		}
+		kind, message := events.LastPodMilestone(&pods[i])
+		if kind == "" {
+			continue
+		}
+		code := events.Event{Kind: kind}.Code()
+		if rank[code] > rank[worst] {
+			worst = code
+			worstMessage = message
+		}
+	}
+
+	return worst, worstMessage
+}
+
+// returns true if min_replicas are not ready and no updated replicas have errored,
+// or if the readiness checker registry reports any of the API's live resources (built-in
+// or, when api is known, its `resources:` extras) as not ready. api is nil wherever the
+// desired spec hasn't been resolved yet (e.g. refreshAPIOnCluster's pre-redeploy check),
+// in which case the extras are skipped.
+func isAPIUpdating(target ClusterTarget, api *spec.API, deployment *kapps.Deployment) (bool, error) {
+	pods, err := target.K8s.ListPodsByLabel("apiName", deployment.Labels["apiName"])
 	if err != nil {
 		return false, err
 	}
@@ -336,6 +615,68 @@ func isAPIUpdating(deployment *kapps.Deployment) (bool, error) {
 		return true, nil
 	}
 
+	// a crashlooping/OOMKilled rollout isn't "updating" - it's stuck, and should
+	// be redeployable without --force just like the baseline TotalFailed() case above
+	if code, _ := rolloutStatus(deployment, pods); code != batch.Complete && code != batch.PodReady &&
+		code != batch.RolloutFailed && code != batch.OOMKilled {
+		return true, nil
+	}
+
+	service, err := target.K8s.GetService(K8sName(deployment.Labels["apiName"]))
+	if err != nil {
+		return false, err
+	}
+	virtualService, err := target.K8s.GetVirtualService(K8sName(deployment.Labels["apiName"]))
+	if err != nil {
+		return false, err
+	}
+
+	objs := []runtime.Object{deployment}
+	if service != nil {
+		objs = append(objs, service)
+	}
+	if virtualService != nil {
+		objs = append(objs, virtualService)
+	}
+
+	for _, obj := range objs {
+		checker := readiness.For(obj)
+		if checker == nil {
+			continue
+		}
+		ready, _, err := checker.IsReady(obj)
+		if err != nil {
+			return false, err
+		}
+		if !ready {
+			return true, nil
+		}
+	}
+
+	if api != nil {
+		for _, res := range api.ExtraResources {
+			plugin := statusplugin.Get(res.GroupVersionKind())
+			if plugin == nil {
+				continue
+			}
+			live, err := plugin.Get(target.K8s, res.GetName())
+			if err != nil {
+				return false, err
+			}
+			if live == nil {
+				// not applied yet, so there's nothing to wait on
+				continue
+			}
+			code, _, err := plugin.Status(live)
+			if err != nil {
+				return false, err
+			}
+			if code != batch.Complete {
+				return true, nil
+			}
+		}
+	}
+
 	return false, nil
 }
 
@@ -371,12 +712,34 @@ func extractCortexAnnotations(obj kmeta.Object) map[string]string {
 	return cortexAnnotations
 }
 
-func IsAPIDeployed(apiName string) (bool, error) {
-	virtualService, err := config.K8s.GetVirtualService(K8sName(apiName))
-	if err != nil {
-		return false, err
+// IsAPIDeployed reports whether apiName is deployed on every target in targets
+// (the local cluster if targets is empty), keyed by ClusterTarget.Name.
+func IsAPIDeployed(apiName string, targets ...ClusterTarget) (map[string]bool, error) {
+	if len(targets) == 0 {
+		targets = []ClusterTarget{defaultClusterTarget()}
 	}
-	return virtualService != nil, nil
+
+	var resultsMux sync.Mutex
+	results := make(map[string]bool, len(targets))
+	var resultsErr error
+	funcs := make([]func() error, len(targets))
+	for i := range targets {
+		target := targets[i]
+		funcs[i] = func() error {
+			virtualService, err := target.K8s.GetVirtualService(K8sName(apiName))
+			resultsMux.Lock()
+			defer resultsMux.Unlock()
+			if err != nil {
+				resultsErr = err
+				return nil
+			}
+			results[target.Name] = virtualService != nil
+			return nil
+		}
+	}
+
+	parallel.RunFirstErr(funcs...)
+	return results, resultsErr
 }
 
 // TODO remove duplicate
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+)
+
+// ClusterTarget is everything UpdateAPI/DeleteAPI/RefreshAPI need to act on one
+// cluster: its Kubernetes and AWS clients and the S3 bucket its API specs live in.
+type ClusterTarget struct {
+	Name   string
+	K8s    *k8s.Client
+	AWS    *aws.Client
+	Bucket string
+}
+
+var (
+	_clusterRegistryMux sync.Mutex
+	_clusterRegistry    = map[string]ClusterTarget{}
+)
+
+// RegisterClusterTarget adds target to the registry cmd/operator populates at
+// startup, keyed by target.Name. Re-registering a name overwrites it.
+func RegisterClusterTarget(target ClusterTarget) {
+	_clusterRegistryMux.Lock()
+	defer _clusterRegistryMux.Unlock()
+	_clusterRegistry[target.Name] = target
+}
+
+// defaultClusterTarget wraps the process's local cluster config (config.K8s,
+// config.AWS, config.Cluster) as a ClusterTarget, so single-cluster callers that
+// don't pass any targets keep working exactly as before.
+func defaultClusterTarget() ClusterTarget {
+	return ClusterTarget{
+		Name:   config.Cluster.ClusterName,
+		K8s:    config.K8s,
+		AWS:    config.AWS,
+		Bucket: config.Cluster.Bucket,
+	}
+}
+
+// ResolveClusterTargets turns the names a user passed on the CLI (or "all") into
+// concrete ClusterTargets. No names (or exactly "all" with an empty registry)
+// resolves to the local cluster, so every existing single-cluster call site is
+// unaffected.
+func ResolveClusterTargets(names []string) ([]ClusterTarget, error) {
+	_clusterRegistryMux.Lock()
+	defer _clusterRegistryMux.Unlock()
+
+	if len(names) == 0 {
+		return []ClusterTarget{defaultClusterTarget()}, nil
+	}
+
+	if len(names) == 1 && names[0] == "all" {
+		if len(_clusterRegistry) == 0 {
+			return []ClusterTarget{defaultClusterTarget()}, nil
+		}
+		targets := make([]ClusterTarget, 0, len(_clusterRegistry))
+		for _, target := range _clusterRegistry {
+			targets = append(targets, target)
+		}
+		return targets, nil
+	}
+
+	targets := make([]ClusterTarget, 0, len(names))
+	for _, name := range names {
+		target, ok := _clusterRegistry[name]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("%s: cluster is not registered", name))
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// DeployResult is one cluster's outcome from a fanned-out UpdateAPI call.
+// DeleteAPI and RefreshAPI report only a per-cluster error.
+type DeployResult struct {
+	API     *spec.API
+	Message string
+	Err     error
+}
+
+func clusterAPIKey(clusterName, apiName string) string {
+	return clusterName + "/" + apiName
+}
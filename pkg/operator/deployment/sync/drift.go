@@ -0,0 +1,325 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+
+	"github.com/cortexlabs/cortex/pkg/lib/cron"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/cloud"
+	"github.com/cortexlabs/cortex/pkg/operator/deployment/batch"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// driftCheckInterval is how often each API's live state is diffed against its desired spec.
+const driftCheckInterval = 30 * time.Second
+
+var (
+	_driftCronsMux sync.Mutex
+	_driftCrons    = make(map[string]cron.Cron) // "cluster/apiName" -> cron
+)
+
+// DriftReport records a single observed difference between an API's desired
+// spec (as stored in S3) and the live resources running in the cluster.
+type DriftReport struct {
+	APIName    string    `json:"api_name"`
+	Kind       string    `json:"kind"`
+	JSONPatch  string    `json:"json_patch"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+var (
+	_driftReportsMux sync.Mutex
+	_driftReports    = make(map[string]*DriftReport) // "cluster/apiName" -> most recent report
+)
+
+// StartDriftDetector launches (or restarts) the background cron that periodically
+// diffs apiName's desired spec against its live Deployment/Service/VirtualService
+// on target.
+func StartDriftDetector(target ClusterTarget, apiName string) {
+	key := clusterAPIKey(target.Name, apiName)
+
+	_driftCronsMux.Lock()
+	defer _driftCronsMux.Unlock()
+
+	if prevCron, ok := _driftCrons[key]; ok {
+		prevCron.Cancel()
+	}
+
+	_driftCrons[key] = cron.Run(driftCheckFn(target, apiName), cronErrHandler(key+" drift detector"), driftCheckInterval)
+}
+
+// StopDriftDetector cancels apiName's drift-detection cron on target, if one is running.
+func StopDriftDetector(target ClusterTarget, apiName string) {
+	key := clusterAPIKey(target.Name, apiName)
+
+	_driftCronsMux.Lock()
+	if driftCron, ok := _driftCrons[key]; ok {
+		driftCron.Cancel()
+		delete(_driftCrons, key)
+	}
+	_driftCronsMux.Unlock()
+
+	_driftReportsMux.Lock()
+	delete(_driftReports, key)
+	_driftReportsMux.Unlock()
+}
+
+// GetDriftReport returns the most recently detected drift for apiName on target,
+// or nil if the API's live state currently matches its desired spec.
+func GetDriftReport(target ClusterTarget, apiName string) *DriftReport {
+	_driftReportsMux.Lock()
+	defer _driftReportsMux.Unlock()
+	return _driftReports[clusterAPIKey(target.Name, apiName)]
+}
+
+// DriftStatus reports apiName's drift as a batch.Code, the same scale rolloutStatus
+// uses, so UpdateAPI/`cortex get` can fold drift into the status they already show:
+// batch.Drifted if the last check found a difference, batch.Complete otherwise.
+func DriftStatus(target ClusterTarget, apiName string) (batch.Code, string) {
+	report := GetDriftReport(target, apiName)
+	if report == nil {
+		return batch.Complete, ""
+	}
+	return batch.Drifted, fmt.Sprintf("%s has drifted from its desired spec", report.Kind)
+}
+
+// DriftHandler returns an http.HandlerFunc that reports the most recent DriftReport
+// for the apiName given in the "apiName" query param on target, in the style of
+// cortex's existing events.Handler. It responds 204 with no body once the API's
+// live state matches its desired spec again.
+func DriftHandler(target ClusterTarget) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		apiName := r.URL.Query().Get("apiName")
+		if apiName == "" {
+			http.Error(w, "apiName is required", http.StatusBadRequest)
+			return
+		}
+
+		report := GetDriftReport(target, apiName)
+		if report == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+func driftCheckFn(target ClusterTarget, apiName string) func() error {
+	return func() error {
+		return checkDrift(target, apiName)
+	}
+}
+
+func checkDrift(target ClusterTarget, apiName string) error {
+	deployment, service, virtualService, err := getK8sResources(target, apiName)
+	if err != nil {
+		return err
+	}
+	if deployment == nil {
+		// API was deleted out from under the cron; StopDriftDetector will catch up shortly
+		return nil
+	}
+
+	apiID, err := k8s.GetLabel(deployment, "apiID")
+	if err != nil {
+		return err
+	}
+
+	desired, err := cloud.DownloadAPISpec(apiName, apiID)
+	if err != nil {
+		return err
+	}
+
+	deploymentID := deployment.Labels["deploymentID"]
+	wantAPI := spec.GetAPISpec(desired.API, desired.ProjectID, deploymentID)
+
+	report, err := diffResources(apiName, wantAPI, deployment, service, virtualService)
+	if err != nil {
+		return err
+	}
+
+	_driftReportsMux.Lock()
+	_driftReports[clusterAPIKey(target.Name, apiName)] = report
+	_driftReportsMux.Unlock()
+
+	if report != nil && desired.API.AutoRepair {
+		return applyK8sResources(target, wantAPI, deployment, service, virtualService)
+	}
+
+	return nil
+}
+
+// diffResources diffs each live resource against its desired spec in turn and
+// returns the first drift found (Deployment, then Service, then VirtualService).
+// DriftReport models a single Kind's patch, so a single check only ever surfaces
+// one drifted resource per run; the next tick picks up whatever's left.
+func diffResources(apiName string, wantAPI *spec.API, haveDeployment *kapps.Deployment, haveService *kcore.Service, haveVirtualService *kunstructured.Unstructured) (*DriftReport, error) {
+	wantDeployment := DeploymentSpec(wantAPI, haveDeployment)
+	if report, err := diffDeployment(apiName, wantDeployment, haveDeployment); err != nil || report != nil {
+		return report, err
+	}
+
+	wantService := serviceSpec(wantAPI)
+	if report, err := diffService(apiName, wantService, haveService); err != nil || report != nil {
+		return report, err
+	}
+
+	wantVirtualService := virtualServiceSpec(wantAPI)
+	return diffVirtualService(apiName, wantVirtualService, haveVirtualService)
+}
+
+// diffDeployment reports drift across container images, env vars, resource
+// requests/limits (via k8s.PodComputesEqual) and cortex annotations. Istio route
+// weights are NOT among these - see diffVirtualService - they live in the
+// VirtualService's spec, not in any Deployment annotation.
+func diffDeployment(apiName string, want, have *kapps.Deployment) (*DriftReport, error) {
+	wantSpec := want.Spec.Template.Spec
+	haveSpec := have.Spec.Template.Spec
+
+	if podComputesDrifted(&wantSpec, &haveSpec) || !doCortexAnnotationsMatch(want, have) {
+		patch, err := jsonMergePatch(want, have)
+		if err != nil {
+			return nil, err
+		}
+		return &DriftReport{
+			APIName:    apiName,
+			Kind:       "Deployment",
+			JSONPatch:  patch,
+			DetectedAt: time.Now(),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// diffService reports drift in the Service's selector, ports and cortex annotations.
+func diffService(apiName string, want, have *kcore.Service) (*DriftReport, error) {
+	if have == nil {
+		return nil, nil
+	}
+
+	if !reflect.DeepEqual(want.Spec.Selector, have.Spec.Selector) ||
+		!reflect.DeepEqual(want.Spec.Ports, have.Spec.Ports) ||
+		!doCortexAnnotationsMatch(want, have) {
+		patch, err := jsonMergePatch(want, have)
+		if err != nil {
+			return nil, err
+		}
+		return &DriftReport{
+			APIName:    apiName,
+			Kind:       "Service",
+			JSONPatch:  patch,
+			DetectedAt: time.Now(),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// diffVirtualService reports drift in the VirtualService's spec - this is where
+// Istio route weights actually live, so editing them directly (bypassing cortex)
+// is only detectable here, not via diffDeployment.
+func diffVirtualService(apiName string, want, have *kunstructured.Unstructured) (*DriftReport, error) {
+	if have == nil {
+		return nil, nil
+	}
+
+	wantSpec, _, err := kunstructured.NestedMap(want.Object, "spec")
+	if err != nil {
+		return nil, err
+	}
+	haveSpec, _, err := kunstructured.NestedMap(have.Object, "spec")
+	if err != nil {
+		return nil, err
+	}
+
+	if !reflect.DeepEqual(wantSpec, haveSpec) {
+		patch, err := jsonMergePatch(want, have)
+		if err != nil {
+			return nil, err
+		}
+		return &DriftReport{
+			APIName:    apiName,
+			Kind:       "VirtualService",
+			JSONPatch:  patch,
+			DetectedAt: time.Now(),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+func podComputesDrifted(want, have *kcore.PodSpec) bool {
+	if !k8s.PodComputesEqual(want, have) {
+		return true
+	}
+
+	if len(want.Containers) != len(have.Containers) {
+		return true
+	}
+
+	for i := range want.Containers {
+		if want.Containers[i].Image != have.Containers[i].Image {
+			return true
+		}
+		if !reflect.DeepEqual(envMap(want.Containers[i].Env), envMap(have.Containers[i].Env)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func envMap(envVars []kcore.EnvVar) map[string]string {
+	m := make(map[string]string, len(envVars))
+	for _, e := range envVars {
+		m[e.Name] = e.Value
+	}
+	return m
+}
+
+func jsonMergePatch(want, have interface{}) (string, error) {
+	wantBytes, err := json.Marshal(want)
+	if err != nil {
+		return "", err
+	}
+	haveBytes, err := json.Marshal(have)
+	if err != nil {
+		return "", err
+	}
+	patch, err := jsonpatch.CreateMergePatch(haveBytes, wantBytes)
+	if err != nil {
+		return "", err
+	}
+	return string(patch), nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package events turns raw Pod/Event watches into the small, ordered set of
+// milestones a user actually cares about while an API rolls out.
+package events
+
+import (
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/operator/deployment/batch"
+)
+
+// Kind identifies where a Pod is in its rollout lifecycle.
+type Kind string
+
+const (
+	Enqueuing       Kind = "Enqueuing"
+	PullingImage    Kind = "PullingImage"
+	Scheduling      Kind = "Scheduling"
+	PodReady        Kind = "PodReady"
+	RolloutComplete Kind = "RolloutComplete"
+	RolloutFailed   Kind = "RolloutFailed"
+	OOMKilled       Kind = "OOMKilled"
+)
+
+// Event is one milestone observed for a single pod belonging to an API's rollout.
+type Event struct {
+	APIName   string    `json:"api_name"`
+	PodName   string    `json:"pod_name"`
+	Kind      Kind      `json:"kind"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Code maps an event Kind onto the batch.Code scale used elsewhere for API status.
+func (e Event) Code() batch.Code {
+	switch e.Kind {
+	case Enqueuing:
+		return batch.Enqueuing
+	case PullingImage:
+		return batch.PullingImage
+	case Scheduling:
+		return batch.Scheduling
+	case PodReady:
+		return batch.PodReady
+	case RolloutComplete:
+		return batch.RolloutComplete
+	case RolloutFailed:
+		return batch.RolloutFailed
+	case OOMKilled:
+		return batch.OOMKilled
+	default:
+		return batch.Unknown
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// Handler returns an http.HandlerFunc that server-sends StreamAPIEvents for the
+// apiName given in the "apiName" query param, in the style of cortex's existing
+// /streamlogs endpoint.
+func Handler(clientset kubernetes.Interface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		apiName := r.URL.Query().Get("apiName")
+		if apiName == "" {
+			http.Error(w, "apiName is required", http.StatusBadRequest)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+
+		out := make(chan Event)
+		ctx := r.Context()
+
+		go StreamAPIEvents(ctx, clientset, apiName, out)
+
+		// out is never closed: the informer goroutines StreamAPIEvents starts may
+		// still be in the middle of a send when ctx is cancelled, so closing it
+		// here could race a send on a closed channel. Tear down on ctx.Done()
+		// instead and let out be garbage collected once nothing references it.
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-out:
+				payload, err := json.Marshal(event)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "data: %s\n\n", payload)
+				flusher.Flush()
+			}
+		}
+	}
+}
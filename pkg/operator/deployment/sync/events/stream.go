@@ -0,0 +1,145 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	kcore "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// StreamAPIEvents watches Pods (and the Events that reference them) labeled apiName=apiName
+// and emits the rollout milestones they produce, until ctx is cancelled.
+func StreamAPIEvents(ctx context.Context, clientset kubernetes.Interface, apiName string, out chan<- Event) error {
+	selector := labels.SelectorFromSet(labels.Set{"apiName": apiName}).String()
+
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0,
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = selector
+		}),
+	)
+
+	podInformer := factory.Core().V1().Pods().Informer()
+	podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			emitPodEvents(ctx, apiName, obj, out)
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			emitPodEvents(ctx, apiName, newObj, out)
+		},
+	})
+
+	eventInformer := factory.Core().V1().Events().Informer()
+	eventInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			emitK8sEvent(ctx, apiName, obj, out)
+		},
+	})
+
+	factory.Start(ctx.Done())
+	factory.WaitForCacheSync(ctx.Done())
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func emitPodEvents(ctx context.Context, apiName string, obj interface{}, out chan<- Event) {
+	pod, ok := obj.(*kcore.Pod)
+	if !ok {
+		return
+	}
+
+	kind, message := LastPodMilestone(pod)
+	if kind == "" {
+		return
+	}
+
+	send(ctx, out, Event{APIName: apiName, PodName: pod.Name, Kind: kind, Message: message, Timestamp: time.Now()})
+}
+
+// LastPodMilestone inspects pod's conditions and container statuses and returns
+// the most recent rollout milestone it has reached (empty Kind if none yet).
+// Both StreamAPIEvents and the sync package's isAPIUpdating use this so "is it
+// updating" and "why" always agree.
+func LastPodMilestone(pod *kcore.Pod) (Kind, string) {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == kcore.PodReady && cond.Status == kcore.ConditionTrue {
+			return PodReady, ""
+		}
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil && status.State.Terminated.Reason == "OOMKilled" {
+			return OOMKilled, status.State.Terminated.Reason
+		}
+		if status.State.Waiting != nil && strings.Contains(status.State.Waiting.Reason, "ImagePull") {
+			return PullingImage, status.State.Waiting.Message
+		}
+		if status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff" {
+			return RolloutFailed, status.State.Waiting.Message
+		}
+	}
+
+	if pod.Status.Phase == kcore.PodPending {
+		return Scheduling, pod.Status.Reason
+	}
+
+	return "", ""
+}
+
+func emitK8sEvent(ctx context.Context, apiName string, obj interface{}, out chan<- Event) {
+	event, ok := obj.(*kcore.Event)
+	if !ok {
+		return
+	}
+
+	var kind Kind
+	switch event.Reason {
+	case "Pulling":
+		kind = PullingImage
+	case "Scheduled":
+		kind = Scheduling
+	case "Failed", "BackOff":
+		kind = RolloutFailed
+	default:
+		return
+	}
+
+	send(ctx, out, Event{
+		APIName:   apiName,
+		PodName:   event.InvolvedObject.Name,
+		Kind:      kind,
+		Message:   event.Message,
+		Timestamp: event.LastTimestamp.Time,
+	})
+}
+
+// send delivers event to out, but never blocks past ctx's cancellation - once
+// the stream is tearing down, nothing guarantees a consumer is still reading.
+func send(ctx context.Context, out chan<- Event, event Event) {
+	select {
+	case out <- event:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/deployment/batch"
+	"github.com/cortexlabs/cortex/pkg/operator/deployment/sync/readiness"
+	"github.com/cortexlabs/cortex/pkg/operator/deployment/sync/statusplugin"
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// deploymentGVK, serviceGVK and virtualServiceGVK identify the three resource kinds
+// that cortex has always managed directly; they're registered as statusplugin.StatusPlugins
+// below so third-party kinds can sit alongside them in the same lifecycle.
+var (
+	deploymentGVK     = schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	serviceGVK        = schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Service"}
+	virtualServiceGVK = schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1alpha3", Kind: "VirtualService"}
+)
+
+func init() {
+	statusplugin.Register(deploymentGVK, deploymentPlugin{})
+	statusplugin.Register(serviceGVK, servicePlugin{})
+	statusplugin.Register(virtualServiceGVK, virtualServicePlugin{})
+}
+
+// readinessStatus maps a readiness.Checker result onto the batch.Code scale shared
+// with batch APIs, so `cortex get` can render both kinds of API from one status enum.
+func readinessStatus(obj runtime.Object) (batch.Code, string, error) {
+	checker := readiness.For(obj)
+	if checker == nil {
+		return batch.Unknown, "", nil
+	}
+
+	ready, reason, err := checker.IsReady(obj)
+	if err != nil {
+		return batch.Unknown, "", err
+	}
+	if !ready {
+		return batch.Running, reason, nil
+	}
+	return batch.Complete, "", nil
+}
+
+// toUnstructured converts a typed k8s object into the unstructured.Unstructured
+// form statusplugin.StatusPlugin deals in.
+func toUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	o, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: o}, nil
+}
+
+type deploymentPlugin struct{}
+
+func (deploymentPlugin) Get(k8sClient *k8s.Client, name string) (*unstructured.Unstructured, error) {
+	deployment, err := k8sClient.GetDeployment(K8sName(name))
+	if err != nil || deployment == nil {
+		return nil, err
+	}
+	return toUnstructured(deployment)
+}
+
+func (deploymentPlugin) Apply(k8sClient *k8s.Client, obj, prev *unstructured.Unstructured) error {
+	deployment := &kapps.Deployment{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, deployment); err != nil {
+		return err
+	}
+
+	var prevDeployment *kapps.Deployment
+	if prev != nil {
+		prevDeployment = &kapps.Deployment{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(prev.Object, prevDeployment); err != nil {
+			return err
+		}
+	}
+
+	if prevDeployment == nil {
+		_, err := k8sClient.CreateDeployment(deployment)
+		return err
+	} else if prevDeployment.Status.ReadyReplicas == 0 {
+		k8sClient.DeleteDeployment(deployment.Name)
+		_, err := k8sClient.CreateDeployment(deployment)
+		return err
+	}
+	_, err := k8sClient.UpdateDeployment(deployment)
+	return err
+}
+
+func (deploymentPlugin) Delete(k8sClient *k8s.Client, name string) error {
+	_, err := k8sClient.DeleteDeployment(K8sName(name))
+	return err
+}
+
+func (deploymentPlugin) Status(obj *unstructured.Unstructured) (batch.Code, string, error) {
+	deployment := &kapps.Deployment{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, deployment); err != nil {
+		return batch.Unknown, "", err
+	}
+	return readinessStatus(deployment)
+}
+
+type servicePlugin struct{}
+
+func (servicePlugin) Get(k8sClient *k8s.Client, name string) (*unstructured.Unstructured, error) {
+	service, err := k8sClient.GetService(K8sName(name))
+	if err != nil || service == nil {
+		return nil, err
+	}
+	return toUnstructured(service)
+}
+
+func (servicePlugin) Apply(k8sClient *k8s.Client, obj, prev *unstructured.Unstructured) error {
+	service := &kcore.Service{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, service); err != nil {
+		return err
+	}
+
+	if prev == nil {
+		_, err := k8sClient.CreateService(service)
+		return err
+	}
+
+	prevService := &kcore.Service{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(prev.Object, prevService); err != nil {
+		return err
+	}
+
+	_, err := k8sClient.UpdateService(prevService, service)
+	return err
+}
+
+func (servicePlugin) Delete(k8sClient *k8s.Client, name string) error {
+	_, err := k8sClient.DeleteService(K8sName(name))
+	return err
+}
+
+func (servicePlugin) Status(obj *unstructured.Unstructured) (batch.Code, string, error) {
+	service := &kcore.Service{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, service); err != nil {
+		return batch.Unknown, "", err
+	}
+	return readinessStatus(service)
+}
+
+type virtualServicePlugin struct{}
+
+func (virtualServicePlugin) Get(k8sClient *k8s.Client, name string) (*unstructured.Unstructured, error) {
+	return k8sClient.GetVirtualService(K8sName(name))
+}
+
+func (virtualServicePlugin) Apply(k8sClient *k8s.Client, obj, prev *unstructured.Unstructured) error {
+	if prev == nil {
+		_, err := k8sClient.CreateVirtualService(obj)
+		return err
+	}
+	_, err := k8sClient.UpdateVirtualService(prev, obj)
+	return err
+}
+
+func (virtualServicePlugin) Delete(k8sClient *k8s.Client, name string) error {
+	_, err := k8sClient.DeleteVirtualService(K8sName(name))
+	return err
+}
+
+func (virtualServicePlugin) Status(obj *unstructured.Unstructured) (batch.Code, string, error) {
+	return readinessStatus(obj)
+}
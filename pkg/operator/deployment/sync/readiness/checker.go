@@ -0,0 +1,264 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package readiness determines whether the resources backing an API have
+// converged on their desired state, the same way `helm upgrade --wait` tells
+// you an install finished rather than just "started".
+package readiness
+
+import (
+	"fmt"
+
+	kapps "k8s.io/api/apps/v1"
+	kbatch "k8s.io/api/batch/v1"
+	kcore "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Checker reports whether a single live object has reached its desired state.
+// reason is a short human-readable explanation and is only meaningful when ready is false.
+type Checker interface {
+	IsReady(obj runtime.Object) (ready bool, reason string, err error)
+}
+
+type CheckerFunc func(obj runtime.Object) (bool, string, error)
+
+func (f CheckerFunc) IsReady(obj runtime.Object) (bool, string, error) {
+	return f(obj)
+}
+
+var _registry = map[schema.GroupVersionKind]Checker{}
+
+func init() {
+	Register(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, CheckerFunc(deploymentIsReady))
+	Register(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"}, CheckerFunc(statefulSetIsReady))
+	Register(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "DaemonSet"}, CheckerFunc(daemonSetIsReady))
+	Register(schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Service"}, CheckerFunc(serviceIsReady))
+	Register(schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1alpha3", Kind: "VirtualService"}, CheckerFunc(virtualServiceIsReady))
+	Register(schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}, CheckerFunc(jobIsReady))
+	Register(schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"}, CheckerFunc(podIsReady))
+}
+
+// Register installs a Checker for a GVK, overwriting any previous registration.
+func Register(gvk schema.GroupVersionKind, checker Checker) {
+	_registry[gvk] = checker
+}
+
+// For returns the Checker registered for obj's GVK, or nil if none is registered.
+// Typed objects returned by a typed clientset (GetDeployment, GetService, ...)
+// generally carry an empty TypeMeta, so the GVK is resolved from obj's concrete
+// Go type first; only unstructured objects (which carry apiVersion/kind as real
+// data) fall back to GetObjectKind().
+func For(obj runtime.Object) Checker {
+	return _registry[gvkOf(obj)]
+}
+
+// gvkOf resolves obj's GVK from its concrete Go type first, falling back to
+// GetObjectKind() for kinds (like unstructured.Unstructured) that carry a real
+// apiVersion/kind instead of an empty TypeMeta.
+func gvkOf(obj runtime.Object) schema.GroupVersionKind {
+	if gvk, ok := staticGVK(obj); ok {
+		return gvk
+	}
+	return obj.GetObjectKind().GroupVersionKind()
+}
+
+func staticGVK(obj runtime.Object) (schema.GroupVersionKind, bool) {
+	switch obj.(type) {
+	case *kapps.Deployment:
+		return schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, true
+	case *kapps.StatefulSet:
+		return schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"}, true
+	case *kapps.DaemonSet:
+		return schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "DaemonSet"}, true
+	case *kcore.Service:
+		return schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Service"}, true
+	case *kbatch.Job:
+		return schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}, true
+	case *kcore.Pod:
+		return schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"}, true
+	default:
+		return schema.GroupVersionKind{}, false
+	}
+}
+
+func deploymentIsReady(obj runtime.Object) (bool, string, error) {
+	deployment, ok := obj.(*kapps.Deployment)
+	if !ok {
+		return false, "", fmt.Errorf("readiness: expected *apps/v1.Deployment, got %T", obj)
+	}
+
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return false, "waiting for rollout to be observed", nil
+	}
+
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+
+	if deployment.Status.UpdatedReplicas < desired {
+		return false, fmt.Sprintf("%d out of %d new replicas have been updated", deployment.Status.UpdatedReplicas, desired), nil
+	}
+
+	if deployment.Status.AvailableReplicas < desired {
+		return false, fmt.Sprintf("%d out of %d updated replicas are available", deployment.Status.AvailableReplicas, desired), nil
+	}
+
+	if deployment.Status.Replicas > deployment.Status.UpdatedReplicas {
+		return false, fmt.Sprintf("%d old replicas are pending termination", deployment.Status.Replicas-deployment.Status.UpdatedReplicas), nil
+	}
+
+	return true, "", nil
+}
+
+func statefulSetIsReady(obj runtime.Object) (bool, string, error) {
+	sts, ok := obj.(*kapps.StatefulSet)
+	if !ok {
+		return false, "", fmt.Errorf("readiness: expected *apps/v1.StatefulSet, got %T", obj)
+	}
+
+	if sts.Status.ObservedGeneration < sts.Generation {
+		return false, "waiting for rollout to be observed", nil
+	}
+
+	if sts.Spec.UpdateStrategy.RollingUpdate != nil && sts.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
+		partition := *sts.Spec.UpdateStrategy.RollingUpdate.Partition
+		desired := int32(1)
+		if sts.Spec.Replicas != nil {
+			desired = *sts.Spec.Replicas
+		}
+		if sts.Status.UpdatedReplicas < desired-partition {
+			return false, fmt.Sprintf("waiting for partitioned roll out to finish: %d out of %d new pods have been updated", sts.Status.UpdatedReplicas, desired-partition), nil
+		}
+		return true, "", nil
+	}
+
+	if sts.Status.UpdateRevision != sts.Status.CurrentRevision {
+		return false, fmt.Sprintf("waiting for statefulset rolling update to complete: %d pods at revision %s", sts.Status.UpdatedReplicas, sts.Status.UpdateRevision), nil
+	}
+
+	return true, "", nil
+}
+
+func daemonSetIsReady(obj runtime.Object) (bool, string, error) {
+	ds, ok := obj.(*kapps.DaemonSet)
+	if !ok {
+		return false, "", fmt.Errorf("readiness: expected *apps/v1.DaemonSet, got %T", obj)
+	}
+
+	if ds.Status.ObservedGeneration < ds.Generation {
+		return false, "waiting for rollout to be observed", nil
+	}
+
+	if ds.Status.UpdatedNumberScheduled < ds.Status.DesiredNumberScheduled {
+		return false, fmt.Sprintf("%d out of %d new pods have been scheduled", ds.Status.UpdatedNumberScheduled, ds.Status.DesiredNumberScheduled), nil
+	}
+
+	if ds.Status.NumberAvailable < ds.Status.DesiredNumberScheduled {
+		return false, fmt.Sprintf("%d out of %d updated pods are available", ds.Status.NumberAvailable, ds.Status.DesiredNumberScheduled), nil
+	}
+
+	return true, "", nil
+}
+
+func serviceIsReady(obj runtime.Object) (bool, string, error) {
+	service, ok := obj.(*kcore.Service)
+	if !ok {
+		return false, "", fmt.Errorf("readiness: expected *core/v1.Service, got %T", obj)
+	}
+
+	if service.Spec.Type == kcore.ServiceTypeLoadBalancer {
+		if len(service.Status.LoadBalancer.Ingress) == 0 {
+			return false, "waiting for load balancer ingress to be assigned", nil
+		}
+		return true, "", nil
+	}
+
+	if service.Spec.ClusterIP == "" {
+		return false, "waiting for cluster IP to be assigned", nil
+	}
+
+	return true, "", nil
+}
+
+func virtualServiceIsReady(obj runtime.Object) (bool, string, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return false, "", fmt.Errorf("readiness: expected *unstructured.Unstructured, got %T", obj)
+	}
+
+	// VirtualServices don't carry a status subresource prior to Istio's experimental
+	// status controller, so programming is considered complete once it exists and
+	// declares at least one http route.
+	routes, found, err := unstructured.NestedSlice(u.Object, "spec", "http")
+	if err != nil {
+		return false, "", err
+	}
+	if !found || len(routes) == 0 {
+		return false, "virtual service has no http routes configured", nil
+	}
+
+	return true, "", nil
+}
+
+func jobIsReady(obj runtime.Object) (bool, string, error) {
+	job, ok := obj.(*kbatch.Job)
+	if !ok {
+		return false, "", fmt.Errorf("readiness: expected *batch/v1.Job, got %T", obj)
+	}
+
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == kbatch.JobFailed && cond.Status == kcore.ConditionTrue {
+			return false, fmt.Sprintf("job failed: %s", cond.Message), nil
+		}
+	}
+
+	desired := int32(1)
+	if job.Spec.Completions != nil {
+		desired = *job.Spec.Completions
+	}
+
+	if job.Status.Succeeded < desired {
+		return false, fmt.Sprintf("%d out of %d pods have completed", job.Status.Succeeded, desired), nil
+	}
+
+	return true, "", nil
+}
+
+func podIsReady(obj runtime.Object) (bool, string, error) {
+	pod, ok := obj.(*kcore.Pod)
+	if !ok {
+		return false, "", fmt.Errorf("readiness: expected *core/v1.Pod, got %T", obj)
+	}
+
+	if pod.Status.Phase == kcore.PodSucceeded {
+		return true, "", nil
+	}
+
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == kcore.PodReady {
+			if cond.Status == kcore.ConditionTrue {
+				return true, "", nil
+			}
+			return false, cond.Message, nil
+		}
+	}
+
+	return false, fmt.Sprintf("pod is in phase %s", pod.Status.Phase), nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readiness
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Reason maps an object's display name to the reason its Checker reported it isn't ready.
+type Reason struct {
+	Name   string
+	Kind   string
+	Reason string
+}
+
+// Waiter polls a set of objects for readiness, refreshing each from the cluster
+// between polls via GetFn.
+type Waiter struct {
+	// GetFn re-fetches obj from the cluster, returning an up-to-date copy (or the
+	// same object if the client has no GET for its kind).
+	GetFn func(obj runtime.Object) (runtime.Object, error)
+	// PollInterval is how often WaitFor re-checks the objects; defaults to 2s.
+	PollInterval time.Duration
+}
+
+// WaitFor blocks until every obj's registered Checker reports ready, timeout
+// elapses, or ctx is cancelled. It returns the non-ready reasons observed on
+// the final poll (empty on success).
+func (w *Waiter) WaitFor(ctx context.Context, objs []runtime.Object, timeout time.Duration) ([]Reason, error) {
+	interval := w.PollInterval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		reasons, err := w.poll(objs)
+		if err != nil {
+			return nil, err
+		}
+		if len(reasons) == 0 {
+			return nil, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return reasons, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Waiter) poll(objs []runtime.Object) ([]Reason, error) {
+	var reasons []Reason
+
+	for _, obj := range objs {
+		checker := For(obj)
+		if checker == nil {
+			continue
+		}
+
+		live := obj
+		if w.GetFn != nil {
+			refreshed, err := w.GetFn(obj)
+			if err != nil {
+				return nil, err
+			}
+			if refreshed != nil {
+				live = refreshed
+			}
+		}
+
+		ready, reason, err := checker.IsReady(live)
+		if err != nil {
+			return nil, err
+		}
+		if !ready {
+			name := ""
+			if named, ok := live.(interface{ GetName() string }); ok {
+				name = named.GetName()
+			}
+			reasons = append(reasons, Reason{Name: name, Kind: gvkOf(live).Kind, Reason: reason})
+		}
+	}
+
+	return reasons, nil
+}
+
+// Aggregate joins reasons into a single human-readable summary, e.g. for
+// surfacing via an API error or status message.
+func Aggregate(reasons []Reason) string {
+	parts := make([]string, len(reasons))
+	for i, r := range reasons {
+		if r.Name != "" {
+			parts[i] = fmt.Sprintf("%s %s: %s", r.Kind, r.Name, r.Reason)
+		} else {
+			parts[i] = fmt.Sprintf("%s: %s", r.Kind, r.Reason)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package statusplugin lets third parties hook arbitrary resource kinds into
+// the same create/update/delete/status lifecycle that cortex uses for the
+// Deployment, Service and VirtualService it manages for every API.
+package statusplugin
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/deployment/batch"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// StatusPlugin owns the full lifecycle of one resource kind for an API: creating
+// or updating it to match the desired spec, deleting it, and reporting its status.
+// Get/Apply/Delete take the *k8s.Client for the cluster they should act on, so a
+// plugin works the same whether it's driven for the local cluster or fanned out
+// across ClusterTargets.
+type StatusPlugin interface {
+	// Get returns the live resource for name on k8sClient, or nil if it doesn't exist yet.
+	Get(k8sClient *k8s.Client, name string) (*unstructured.Unstructured, error)
+	// Apply creates obj on k8sClient if prev is nil, or updates it to match obj otherwise.
+	Apply(k8sClient *k8s.Client, obj *unstructured.Unstructured, prev *unstructured.Unstructured) error
+	// Delete removes the resource called name from k8sClient, if one exists. The
+	// built-in plugins are passed the API's name; `resources:` extras their own name.
+	Delete(k8sClient *k8s.Client, name string) error
+	// Status reports the resource's current batch.Code and a human-readable message.
+	Status(obj *unstructured.Unstructured) (batch.Code, string, error)
+}
+
+var _registry = map[schema.GroupVersionKind]StatusPlugin{}
+
+// Register installs plugin as the handler for gvk, overwriting any previous registration.
+// Built-in plugins for Deployment, Service and VirtualService are registered by the sync
+// package on import; cmd/operator registers any additional plugins (e.g. for a KEDA
+// ScaledObject or a Knative Service) at startup.
+func Register(gvk schema.GroupVersionKind, plugin StatusPlugin) {
+	_registry[gvk] = plugin
+}
+
+// Get returns the plugin registered for gvk, or nil if none is registered.
+func Get(gvk schema.GroupVersionKind) StatusPlugin {
+	return _registry[gvk]
+}
+
+// All returns every registered GVK, in no particular order.
+func All() []schema.GroupVersionKind {
+	gvks := make([]schema.GroupVersionKind, 0, len(_registry))
+	for gvk := range _registry {
+		gvks = append(gvks, gvk)
+	}
+	return gvks
+}
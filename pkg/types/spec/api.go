@@ -0,0 +1,61 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// AutoscalingTickInterval is how often the per-API autoscaler cron re-evaluates
+// its target replica count (see sync.UpdateAutoscalerCron).
+const AutoscalingTickInterval = 10 * time.Second
+
+// API is the fully-resolved spec for a single deployed API: userconfig.API as
+// written, plus the generated identifiers that tie it to one deployment and
+// the third-party resources it brings along via its `resources:` block.
+type API struct {
+	*userconfig.API
+	ProjectID    string
+	DeploymentID string
+	Key          string
+
+	// ExtraResources are the API's `resources:` manifests decoded into
+	// unstructured objects, ready to hand to a statusplugin.StatusPlugin.
+	ExtraResources []*unstructured.Unstructured
+}
+
+// GetAPISpec resolves apiConfig into a full API spec for deploymentID, deriving
+// its S3 key from apiConfig.Name and deploymentID and decoding its `resources:` entries
+// into ExtraResources.
+func GetAPISpec(apiConfig *userconfig.API, projectID string, deploymentID string) *API {
+	api := &API{
+		API:          apiConfig,
+		ProjectID:    projectID,
+		DeploymentID: deploymentID,
+		Key:          filepath.Join("apis", apiConfig.Name, deploymentID, "spec.msgpack"),
+	}
+
+	for _, resource := range apiConfig.Resources {
+		api.ExtraResources = append(api.ExtraResources, &unstructured.Unstructured{Object: resource})
+	}
+
+	return api
+}
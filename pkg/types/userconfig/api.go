@@ -0,0 +1,61 @@
+/*
+Copyright 2020 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import "fmt"
+
+// API is the user-facing, as-written API spec: exactly what's read from an
+// API's YAML configuration before cortex resolves it into a spec.API.
+type API struct {
+	Name string `json:"name" yaml:"name"`
+
+	// DeploymentTimeout bounds, in seconds, how long UpdateAPI waits for this
+	// API's resources to become ready before giving up and reporting whatever
+	// readiness reasons are outstanding. Zero (the default) defers to the
+	// operator's own defaultDeploymentTimeout.
+	DeploymentTimeout int64 `json:"deployment_timeout" yaml:"deployment_timeout"`
+
+	// AutoRepair lets the drift detector re-apply this API's resources on its
+	// own whenever they've drifted from this spec, instead of only recording
+	// the drift for `cortex get` to display.
+	AutoRepair bool `json:"auto_repair" yaml:"auto_repair"`
+
+	// Resources holds any third-party resource manifests this API brings
+	// along (e.g. a KEDA ScaledObject), applied through the statusplugin
+	// registry alongside the Deployment/Service/VirtualService cortex
+	// manages directly.
+	Resources []map[string]interface{} `json:"resources" yaml:"resources"`
+}
+
+// Validate checks the fields declared above; the rest of API's field
+// validation lives alongside wherever those fields are added.
+func (api *API) Validate() error {
+	if api.DeploymentTimeout < 0 {
+		return fmt.Errorf("%s: deployment_timeout must be >= 0", api.Name)
+	}
+
+	for i, resource := range api.Resources {
+		if _, ok := resource["apiVersion"]; !ok {
+			return fmt.Errorf("%s: resources[%d] is missing apiVersion", api.Name, i)
+		}
+		if _, ok := resource["kind"]; !ok {
+			return fmt.Errorf("%s: resources[%d] is missing kind", api.Name, i)
+		}
+	}
+
+	return nil
+}